@@ -0,0 +1,62 @@
+package adapters
+
+import (
+	"errors"
+	"fmt"
+)
+
+//validSASLMechanisms are the mechanisms sarama.SASLMechanism understands; validated before the value is
+//cast so a typo surfaces as a config error instead of a confusing broker handshake failure
+var validSASLMechanisms = map[string]bool{
+	"PLAIN":         true,
+	"SCRAM-SHA-256": true,
+	"SCRAM-SHA-512": true,
+	"GSSAPI":        true,
+}
+
+//KafkaSASLConfig is SASL authentication configuration for a Kafka broker connection
+type KafkaSASLConfig struct {
+	Mechanism string `mapstructure:"mechanism" json:"mechanism,omitempty" yaml:"mechanism,omitempty"`
+	Username  string `mapstructure:"username" json:"username,omitempty" yaml:"username,omitempty"`
+	Password  string `mapstructure:"password" json:"password,omitempty" yaml:"password,omitempty"`
+	TLS       bool   `mapstructure:"tls" json:"tls,omitempty" yaml:"tls,omitempty"`
+}
+
+//Validate returns err if the SASL configuration is invalid
+func (sc *KafkaSASLConfig) Validate() error {
+	if sc == nil {
+		return nil
+	}
+	if !validSASLMechanisms[sc.Mechanism] {
+		return fmt.Errorf("Kafka sasl.mechanism must be one of [PLAIN, SCRAM-SHA-256, SCRAM-SHA-512, GSSAPI], got: %s", sc.Mechanism)
+	}
+	if sc.Username == "" || sc.Password == "" {
+		return errors.New("Kafka sasl.username and sasl.password are required when sasl is configured")
+	}
+	return nil
+}
+
+//KafkaConfig is a dto for Kafka destination configuration
+type KafkaConfig struct {
+	Brokers []string `mapstructure:"brokers" json:"brokers,omitempty" yaml:"brokers,omitempty"`
+	//Topic supports the same per-event templating as DataLayout.TableNameTemplate so events can be routed
+	//to per-event topics, e.g. "events_{{.event_type}}"
+	Topic             string           `mapstructure:"topic" json:"topic,omitempty" yaml:"topic,omitempty"`
+	Compression       string           `mapstructure:"compression" json:"compression,omitempty" yaml:"compression,omitempty"`
+	SchemaRegistryURL string           `mapstructure:"schema_registry_url" json:"schema_registry_url,omitempty" yaml:"schema_registry_url,omitempty"`
+	SASL              *KafkaSASLConfig `mapstructure:"sasl" json:"sasl,omitempty" yaml:"sasl,omitempty"`
+}
+
+//Validate returns err if configuration is invalid
+func (kc *KafkaConfig) Validate() error {
+	if kc == nil {
+		return errors.New("Kafka config is required")
+	}
+	if len(kc.Brokers) == 0 {
+		return errors.New("Kafka brokers must not be empty")
+	}
+	if kc.Topic == "" {
+		return errors.New("Kafka topic must not be empty")
+	}
+	return kc.SASL.Validate()
+}
@@ -0,0 +1,54 @@
+package adapters
+
+import "testing"
+
+func TestKafkaConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *KafkaConfig
+		wantErr bool
+	}{
+		{
+			name:    "valid without SASL",
+			config:  &KafkaConfig{Brokers: []string{"localhost:9092"}, Topic: "events"},
+			wantErr: false,
+		},
+		{
+			name:    "missing brokers",
+			config:  &KafkaConfig{Topic: "events"},
+			wantErr: true,
+		},
+		{
+			name:    "missing topic",
+			config:  &KafkaConfig{Brokers: []string{"localhost:9092"}},
+			wantErr: true,
+		},
+		{
+			name: "invalid SASL mechanism",
+			config: &KafkaConfig{
+				Brokers: []string{"localhost:9092"},
+				Topic:   "events",
+				SASL:    &KafkaSASLConfig{Mechanism: "NOT-A-MECHANISM", Username: "u", Password: "p"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid SASL",
+			config: &KafkaConfig{
+				Brokers: []string{"localhost:9092"},
+				Topic:   "events",
+				SASL:    &KafkaSASLConfig{Mechanism: "SCRAM-SHA-256", Username: "u", Password: "p"},
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
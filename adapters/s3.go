@@ -0,0 +1,79 @@
+package adapters
+
+import "fmt"
+
+//S3EncryptionConfig configures server-side encryption applied to every object Jitsu uploads
+type S3EncryptionConfig struct {
+	//Algorithm is either AES256 or aws:kms
+	Algorithm        string `mapstructure:"algorithm" json:"algorithm,omitempty" yaml:"algorithm,omitempty"`
+	KMSKeyID         string `mapstructure:"kms_key_id" json:"kms_key_id,omitempty" yaml:"kms_key_id,omitempty"`
+	BucketKeyEnabled bool   `mapstructure:"bucket_key_enabled" json:"bucket_key_enabled,omitempty" yaml:"bucket_key_enabled,omitempty"`
+}
+
+//Validate returns err if the encryption configuration is invalid
+func (ec *S3EncryptionConfig) Validate() error {
+	if ec == nil {
+		return nil
+	}
+	if ec.Algorithm != "AES256" && ec.Algorithm != "aws:kms" {
+		return fmt.Errorf("s3.encryption.algorithm must be one of [AES256, aws:kms], got: %s", ec.Algorithm)
+	}
+	if ec.Algorithm == "aws:kms" && ec.KMSKeyID == "" {
+		return fmt.Errorf("s3.encryption.kms_key_id is required when algorithm is aws:kms")
+	}
+	return nil
+}
+
+//S3LifecycleConfig configures a bucket lifecycle rule that Jitsu applies on startup so event archives
+//are retained and cleaned up without out-of-band bucket configuration
+type S3LifecycleConfig struct {
+	Prefix                      string `mapstructure:"prefix" json:"prefix,omitempty" yaml:"prefix,omitempty"`
+	DaysToGlacier               int64  `mapstructure:"days_to_glacier" json:"days_to_glacier,omitempty" yaml:"days_to_glacier,omitempty"`
+	DaysToExpire                int64  `mapstructure:"days_to_expire" json:"days_to_expire,omitempty" yaml:"days_to_expire,omitempty"`
+	AbortIncompleteMultipartDays int64 `mapstructure:"abort_incomplete_multipart_days" json:"abort_incomplete_multipart_days,omitempty" yaml:"abort_incomplete_multipart_days,omitempty"`
+}
+
+//Validate returns err if the lifecycle configuration is invalid
+func (lc *S3LifecycleConfig) Validate() error {
+	if lc == nil {
+		return nil
+	}
+	if lc.DaysToGlacier < 0 || lc.DaysToExpire < 0 || lc.AbortIncompleteMultipartDays < 0 {
+		return fmt.Errorf("s3.lifecycle day values must not be negative")
+	}
+	if lc.DaysToGlacier > 0 && lc.DaysToExpire > 0 && lc.DaysToGlacier >= lc.DaysToExpire {
+		return fmt.Errorf("s3.lifecycle.days_to_glacier must be less than days_to_expire")
+	}
+	return nil
+}
+
+//S3Config is a dto for S3 destination configuration
+type S3Config struct {
+	AccessKeyID string `mapstructure:"access_key_id" json:"access_key_id,omitempty" yaml:"access_key_id,omitempty"`
+	SecretKey   string `mapstructure:"secret_access_key" json:"secret_access_key,omitempty" yaml:"secret_access_key,omitempty"`
+	Bucket      string `mapstructure:"bucket" json:"bucket,omitempty" yaml:"bucket,omitempty"`
+	Region      string `mapstructure:"region" json:"region,omitempty" yaml:"region,omitempty"`
+	Endpoint    string `mapstructure:"endpoint" json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	Folder      string `mapstructure:"folder" json:"folder,omitempty" yaml:"folder,omitempty"`
+	Format      string `mapstructure:"format" json:"format,omitempty" yaml:"format,omitempty"`
+
+	Encryption *S3EncryptionConfig `mapstructure:"encryption" json:"encryption,omitempty" yaml:"encryption,omitempty"`
+	Lifecycle  *S3LifecycleConfig  `mapstructure:"lifecycle" json:"lifecycle,omitempty" yaml:"lifecycle,omitempty"`
+}
+
+//Validate returns err if the S3 configuration (including encryption/lifecycle) is invalid
+func (s3c *S3Config) Validate() error {
+	if s3c == nil {
+		return fmt.Errorf("S3 config is required")
+	}
+	if s3c.Bucket == "" {
+		return fmt.Errorf("S3 bucket must not be empty")
+	}
+	if err := s3c.Encryption.Validate(); err != nil {
+		return err
+	}
+	if err := s3c.Lifecycle.Validate(); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package enrichment
+
+import "github.com/jitsucom/eventnative/events"
+
+const (
+	jsIPField = "ip"
+	jsUAField = "user_agent"
+)
+
+type jsIPRule struct{}
+
+func (r *jsIPRule) Name() string {
+	return "js_ip"
+}
+
+func (r *jsIPRule) Execute(event events.Event) []EnrichmentError {
+	if value, ok := event[jsIPField]; !ok || value == "" {
+		return []EnrichmentError{{Rule: r.Name(), Field: jsIPField, Error: "source IP is missing"}}
+	}
+	return nil
+}
+
+type jsUARule struct{}
+
+func (r *jsUARule) Name() string {
+	return "js_ua"
+}
+
+func (r *jsUARule) Execute(event events.Event) []EnrichmentError {
+	if value, ok := event[jsUAField]; !ok || value == "" {
+		return []EnrichmentError{{Rule: r.Name(), Field: jsUAField, Error: "user agent is missing"}}
+	}
+	return nil
+}
+
+//DefaultJsIpRule requires the client IP to be present on every event
+var DefaultJsIpRule Rule = &jsIPRule{}
+
+//DefaultJsUaRule requires the client user agent to be present on every event
+var DefaultJsUaRule Rule = &jsUARule{}
@@ -0,0 +1,63 @@
+package enrichment
+
+import (
+	"fmt"
+
+	"github.com/jitsucom/eventnative/events"
+)
+
+//EnrichmentError is a single failure encountered while running a Rule against an event. It is accumulated
+//rather than treated as fatal so the event can still be persisted - to the primary destination, or to an
+//incomplete-events sidechannel - for inspection and replay, following the Snowplow enrich "bad rows"
+//approach.
+type EnrichmentError struct {
+	Rule  string `json:"rule"`
+	Field string `json:"field,omitempty"`
+	Error string `json:"error"`
+}
+
+func (ee EnrichmentError) String() string {
+	if ee.Field != "" {
+		return fmt.Sprintf("%s[%s]: %s", ee.Rule, ee.Field, ee.Error)
+	}
+	return fmt.Sprintf("%s: %s", ee.Rule, ee.Error)
+}
+
+//ErrorsToStrings renders a list of EnrichmentError as strings for embedding in an event's derived_errors field
+func ErrorsToStrings(errs []EnrichmentError) []string {
+	strs := make([]string, len(errs))
+	for i, err := range errs {
+		strs[i] = err.String()
+	}
+	return strs
+}
+
+//Rule enriches a single event in place. It never aborts the pipeline itself: any failures are returned as
+//a list of EnrichmentError so the caller can run every configured rule to completion instead of stopping
+//at the first one.
+type Rule interface {
+	Name() string
+	Execute(event events.Event) []EnrichmentError
+}
+
+//RuleConfig is user configuration for a single enrichment rule
+type RuleConfig struct {
+	Name       string                 `mapstructure:"name" json:"name,omitempty" yaml:"name,omitempty"`
+	Parameters map[string]interface{} `mapstructure:"parameters" json:"parameters,omitempty" yaml:"parameters,omitempty"`
+}
+
+func (rc *RuleConfig) String() string {
+	return fmt.Sprintf("%s %v", rc.Name, rc.Parameters)
+}
+
+//NewRule builds a Rule from its configuration
+func NewRule(config *RuleConfig) (Rule, error) {
+	switch config.Name {
+	case "js_ip":
+		return DefaultJsIpRule, nil
+	case "js_ua":
+		return DefaultJsUaRule, nil
+	default:
+		return nil, fmt.Errorf("Unknown enrichment rule: %s", config.Name)
+	}
+}
@@ -0,0 +1,16 @@
+package events
+
+//Event is a single, JSON-like tracked event flowing through the enrichment/mapping pipeline
+type Event map[string]interface{}
+
+//StorageProxy is the common interface every destination exposes to the rest of the app, regardless of
+//destination type or mode (batch/stream)
+type StorageProxy interface {
+	//Insert routes, enriches/maps and persists a single event
+	Insert(event Event) error
+	//RoutingStats returns the number of events that matched a routing rule, fell through unmatched, and
+	//were dropped by a matching rule, so callers can surface per-destination routing metrics. All zero when
+	//no routing rules are configured.
+	RoutingStats() (matched, unmatched, dropped int64)
+	Close() error
+}
@@ -0,0 +1,24 @@
+package events
+
+import "fmt"
+
+//PersistentQueue identifies the on-disk event queue of a stream-mode destination by name and path. It opens
+//no file and holds no events itself.
+type PersistentQueue struct {
+	name string
+	path string
+}
+
+//NewPersistentQueue returns a queue for path; path must not be empty, and no file is opened
+func NewPersistentQueue(name, path string) (*PersistentQueue, error) {
+	if path == "" {
+		return nil, fmt.Errorf("[%s] queue log path must not be empty", name)
+	}
+
+	return &PersistentQueue{name: name, path: path}, nil
+}
+
+//Close is a no-op: the queue holds no open file
+func (q *PersistentQueue) Close() error {
+	return nil
+}
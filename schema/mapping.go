@@ -0,0 +1,82 @@
+package schema
+
+import (
+	"fmt"
+
+	"github.com/jitsucom/eventnative/enrichment"
+	"github.com/jitsucom/eventnative/events"
+)
+
+//derivedErrorsField is the key the accumulated enrichment errors are attached under on events routed to an
+//incomplete-events destination
+const derivedErrorsField = "derived_errors"
+
+//FieldMapper maps a raw event into the destination's flattened/typed representation
+type FieldMapper interface {
+	Map(event events.Event) (events.Event, error)
+}
+
+//MappingStep runs enrichment rules followed by field mapping against a single event. Enrichment rules run
+//to completion and accumulate every error instead of stopping at the first one, mirroring the Snowplow
+//enrich approach. When breakOnError is set, the first failing enrichment rule stops processing instead and
+//Process returns an error; a field-mapping failure returns an error either way.
+type MappingStep struct {
+	destinationName string
+	tableName       string
+	fieldMapper     FieldMapper
+	enrichmentRules []enrichment.Rule
+	breakOnError    bool
+}
+
+//NewMappingStep returns a configured MappingStep
+func NewMappingStep(destinationName, tableName string, fieldMapper FieldMapper, enrichmentRules []enrichment.Rule, breakOnError bool) (*MappingStep, error) {
+	if fieldMapper == nil {
+		return nil, fmt.Errorf("[%s] field mapper must be configured", destinationName)
+	}
+
+	return &MappingStep{
+		destinationName: destinationName,
+		tableName:       tableName,
+		fieldMapper:     fieldMapper,
+		enrichmentRules: enrichmentRules,
+		breakOnError:    breakOnError,
+	}, nil
+}
+
+//Process runs every enrichment rule against event to completion - collecting every error instead of
+//stopping at the first one, unless breakOnError is set - then maps the event. tableNameOverride, when non-empty (set by a Router rule
+//match), takes precedence over the step's own tableName. The returned error list lets the caller decide
+//whether to route the event to an incomplete-events sidechannel destination instead of the primary one.
+func (ms *MappingStep) Process(event events.Event, tableNameOverride string) (events.Event, string, []enrichment.EnrichmentError, error) {
+	tableName := ms.tableName
+	if tableNameOverride != "" {
+		tableName = tableNameOverride
+	}
+
+	var derivedErrors []enrichment.EnrichmentError
+
+	for _, rule := range ms.enrichmentRules {
+		if errs := rule.Execute(event); len(errs) > 0 {
+			derivedErrors = append(derivedErrors, errs...)
+			if ms.breakOnError {
+				return event, tableName, derivedErrors, fmt.Errorf("[%s] Error executing enrichment rule [%s]: %s", ms.destinationName, rule.Name(), errs[0].Error)
+			}
+		}
+	}
+
+	mapped, err := ms.fieldMapper.Map(event)
+	if err != nil {
+		return event, tableName, derivedErrors, fmt.Errorf("[%s] Error mapping event: %v", ms.destinationName, err)
+	}
+
+	if len(derivedErrors) > 0 {
+		mapped[derivedErrorsField] = enrichment.ErrorsToStrings(derivedErrors)
+	}
+
+	return mapped, tableName, derivedErrors, nil
+}
+
+//TableName returns the table name this step maps events into
+func (ms *MappingStep) TableName() string {
+	return ms.tableName
+}
@@ -0,0 +1,66 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/jitsucom/eventnative/enrichment"
+	"github.com/jitsucom/eventnative/events"
+)
+
+type passthroughMapper struct{}
+
+func (passthroughMapper) Map(event events.Event) (events.Event, error) {
+	return event, nil
+}
+
+type failingRule struct {
+	name string
+}
+
+func (r failingRule) Name() string {
+	return r.name
+}
+
+func (r failingRule) Execute(event events.Event) []enrichment.EnrichmentError {
+	return []enrichment.EnrichmentError{{Rule: r.name, Error: "boom"}}
+}
+
+func TestMappingStepProcessAccumulatesAllErrors(t *testing.T) {
+	rules := []enrichment.Rule{failingRule{name: "rule_1"}, failingRule{name: "rule_2"}}
+
+	step, err := NewMappingStep("test_dst", "events", passthroughMapper{}, rules, false)
+	if err != nil {
+		t.Fatalf("NewMappingStep() unexpected error: %v", err)
+	}
+
+	mapped, _, derivedErrors, err := step.Process(events.Event{"event_type": "pageview"}, "")
+	if err != nil {
+		t.Fatalf("Process() unexpected error: %v", err)
+	}
+
+	if len(derivedErrors) != 2 {
+		t.Fatalf("expected errors from both rules to be accumulated, got %d: %v", len(derivedErrors), derivedErrors)
+	}
+
+	if _, ok := mapped[derivedErrorsField]; !ok {
+		t.Fatalf("expected mapped event to carry %s, got %v", derivedErrorsField, mapped)
+	}
+}
+
+func TestMappingStepProcessBreaksOnErrorWhenConfigured(t *testing.T) {
+	rules := []enrichment.Rule{failingRule{name: "rule_1"}, failingRule{name: "rule_2"}}
+
+	step, err := NewMappingStep("test_dst", "events", passthroughMapper{}, rules, true)
+	if err != nil {
+		t.Fatalf("NewMappingStep() unexpected error: %v", err)
+	}
+
+	_, _, derivedErrors, err := step.Process(events.Event{"event_type": "pageview"}, "")
+	if err == nil {
+		t.Fatalf("expected Process() to return an error when breakOnError is set")
+	}
+
+	if len(derivedErrors) != 1 {
+		t.Fatalf("expected only the first rule's error to be accumulated, got %d: %v", len(derivedErrors), derivedErrors)
+	}
+}
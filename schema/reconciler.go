@@ -0,0 +1,179 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jitsucom/eventnative/logging"
+)
+
+//ReconcileMode controls how the Reconciler treats columns that are present in the last-applied schema
+//but absent from the current batch's desired schema
+type ReconcileMode string
+
+const (
+	//Strict drops columns that are no longer present in the desired schema
+	Strict ReconcileMode = "strict"
+	//Merge leaves previously-applied columns in place untouched (the default)
+	Merge ReconcileMode = "merge"
+	//Additive never drops column structure; columns no longer present in the desired schema are nulled out
+	//instead, so historical rows keep their shape while the column stops being populated going forward
+	Additive ReconcileMode = "additive"
+)
+
+//SchemaStateStore persists the schema Jitsu itself last applied to a destination table, keyed by table
+//name, so the Reconciler can 3-way-diff it against the live DB schema and the newly desired schema
+type SchemaStateStore interface {
+	GetAppliedSchema(destinationName, tableName string) (*Table, error)
+	SaveAppliedSchema(destinationName, tableName string, table *Table) error
+}
+
+//Reconciler computes the minimal ALTER TABLE diff between the last schema Jitsu applied, the live schema
+//reported by information_schema and the schema desired by the current batch, borrowing the
+//last-applied-configuration 3-way-merge approach
+type Reconciler struct {
+	destinationName string
+	mode            ReconcileMode
+	store           SchemaStateStore
+}
+
+//NewReconciler returns a Reconciler that persists applied schema state via store. mode defaults to Merge
+//if empty.
+func NewReconciler(destinationName string, mode ReconcileMode, store SchemaStateStore) *Reconciler {
+	if mode == "" {
+		mode = Merge
+	}
+
+	return &Reconciler{destinationName: destinationName, mode: mode, store: store}
+}
+
+//Reconcile returns the ordered list of ALTER TABLE-worthy column diffs to apply to tableName so that its
+//live schema converges on desired, given what Jitsu last applied itself. A desired column whose live type
+//differs from desired and from the type lastApplied recorded (or that lastApplied never recorded) is logged
+//as out-of-band drift and keeps its live definition. A lastApplied column that is neither desired nor live
+//is logged as dropped out-of-band and left alone. A desired column missing from live is added back, and
+//live columns absent from both lastApplied and desired are left alone without a warning.
+func (r *Reconciler) Reconcile(tableName string, live, desired *Table) ([]ColumnDiff, error) {
+	lastApplied, err := r.store.GetAppliedSchema(r.destinationName, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("[%s] Error reading last-applied schema for [%s]: %v", r.destinationName, tableName, err)
+	}
+	if lastApplied == nil {
+		lastApplied = &Table{Name: tableName, Columns: map[string]Column{}}
+	}
+
+	var diffs []ColumnDiff
+	//driftOverrides tracks columns whose out-of-band live definition must win over desired when the
+	//applied-schema state is persisted below, so the next reconciliation doesn't re-diff against them
+	driftOverrides := map[string]Column{}
+	for name, desiredColumn := range desired.Columns {
+		liveColumn, existsInLive := live.Columns[name]
+
+		if !existsInLive {
+			diffs = append(diffs, ColumnDiff{Name: name, Column: desiredColumn, Op: AddColumn})
+			continue
+		}
+
+		if liveColumn.Type == desiredColumn.Type {
+			continue
+		}
+
+		//a column is only "applied by us" if live still carries the exact type we last recorded for it; if
+		//lastApplied never saw this column, or its recorded type no longer matches what's live, an operator
+		//changed it out-of-band since our last reconciliation and the live definition must win
+		appliedColumn, wasApplied := lastApplied.Columns[name]
+		appliedByUs := wasApplied && appliedColumn.Type == liveColumn.Type
+		if appliedByUs {
+			diffs = append(diffs, ColumnDiff{Name: name, Column: desiredColumn, Op: AlterColumnType})
+		} else {
+			logging.Warnf("[%s] Column [%s.%s] type was changed out-of-band to [%s]; keeping the live definition", r.destinationName, tableName, name, liveColumn.Type)
+			driftOverrides[name] = liveColumn
+		}
+	}
+
+	for name, appliedColumn := range lastApplied.Columns {
+		if _, stillDesired := desired.Columns[name]; stillDesired {
+			continue
+		}
+		if _, removedLive := live.Columns[name]; !removedLive {
+			logging.Warnf("[%s] Column [%s.%s] was dropped out-of-band; leaving it alone", r.destinationName, tableName, name)
+			continue
+		}
+
+		switch r.mode {
+		case Strict:
+			diffs = append(diffs, ColumnDiff{Name: name, Column: appliedColumn, Op: DropColumn})
+		case Additive:
+			//additive never drops structure, it just stops populating the column going forward
+			diffs = append(diffs, ColumnDiff{Name: name, Column: appliedColumn, Op: NullColumn})
+		case Merge:
+			//leave the column in place, still populated
+		}
+	}
+
+	merged := mergeSchemaState(lastApplied, desired, diffs, driftOverrides)
+	if err := r.store.SaveAppliedSchema(r.destinationName, tableName, merged); err != nil {
+		return nil, fmt.Errorf("[%s] Error persisting applied schema for [%s]: %v", r.destinationName, tableName, err)
+	}
+
+	return diffs, nil
+}
+
+//ColumnDiffOp is the kind of change a ColumnDiff represents
+type ColumnDiffOp string
+
+const (
+	AddColumn       ColumnDiffOp = "add"
+	AlterColumnType ColumnDiffOp = "alter_type"
+	DropColumn      ColumnDiffOp = "drop"
+	//NullColumn sets an existing column's values to null and stops populating it, without dropping the
+	//column itself. Emitted by Additive mode instead of DropColumn.
+	NullColumn ColumnDiffOp = "null"
+)
+
+//ColumnDiff is a single column-level change to apply as part of an ALTER TABLE statement
+type ColumnDiff struct {
+	Name   string
+	Column Column
+	Op     ColumnDiffOp
+}
+
+func mergeSchemaState(lastApplied, desired *Table, diffs []ColumnDiff, driftOverrides map[string]Column) *Table {
+	merged := &Table{Name: desired.Name, Columns: map[string]Column{}}
+	for name, column := range lastApplied.Columns {
+		merged.Columns[name] = column
+	}
+	for name, column := range desired.Columns {
+		merged.Columns[name] = column
+	}
+	//columns changed out-of-band are not "applied by us" - keep the live definition so the next
+	//reconciliation doesn't mistake the operator's change for drift needing an ALTER back to desired
+	for name, liveColumn := range driftOverrides {
+		merged.Columns[name] = liveColumn
+	}
+	for _, diff := range diffs {
+		if diff.Op == DropColumn {
+			delete(merged.Columns, diff.Name)
+			continue
+		}
+		//AlterColumnType/AddColumn/NullColumn all keep the column tracked as applied-by-us: a nulled column
+		//is still live (just no longer populated), so a later reintroduction in desired must diff against
+		//its known type instead of reading as out-of-band drift
+		merged.Columns[diff.Name] = diff.Column
+	}
+	return merged
+}
+
+//MarshalSchemaState serializes a Table for storage in a _jitsu_schema_state sidecar record
+func MarshalSchemaState(table *Table) ([]byte, error) {
+	return json.Marshal(table)
+}
+
+//UnmarshalSchemaState deserializes a Table previously persisted via MarshalSchemaState
+func UnmarshalSchemaState(data []byte) (*Table, error) {
+	var table Table
+	if err := json.Unmarshal(data, &table); err != nil {
+		return nil, err
+	}
+	return &table, nil
+}
@@ -0,0 +1,126 @@
+package schema
+
+import "testing"
+
+type fakeSchemaStateStore struct {
+	applied *Table
+}
+
+func (f *fakeSchemaStateStore) GetAppliedSchema(destinationName, tableName string) (*Table, error) {
+	return f.applied, nil
+}
+
+func (f *fakeSchemaStateStore) SaveAppliedSchema(destinationName, tableName string, table *Table) error {
+	f.applied = table
+	return nil
+}
+
+func soleDiff(t *testing.T, diffs []ColumnDiff) ColumnDiff {
+	t.Helper()
+	if len(diffs) != 1 {
+		t.Fatalf("expected exactly 1 diff, got %d: %+v", len(diffs), diffs)
+	}
+	return diffs[0]
+}
+
+func TestReconcileAddsColumnMissingFromLive(t *testing.T) {
+	store := &fakeSchemaStateStore{applied: &Table{Name: "events", Columns: map[string]Column{}}}
+	r := NewReconciler("dst", Merge, store)
+
+	live := &Table{Name: "events", Columns: map[string]Column{}}
+	desired := &Table{Name: "events", Columns: map[string]Column{"new_field": {Type: "varchar"}}}
+
+	diff := soleDiff(t, mustReconcile(t, r, live, desired))
+	if diff.Name != "new_field" || diff.Op != AddColumn {
+		t.Fatalf("expected AddColumn for new_field, got %+v", diff)
+	}
+}
+
+func TestReconcileAltersColumnWeLastApplied(t *testing.T) {
+	store := &fakeSchemaStateStore{applied: &Table{Name: "events", Columns: map[string]Column{
+		"amount": {Type: "int"},
+	}}}
+	r := NewReconciler("dst", Merge, store)
+
+	live := &Table{Name: "events", Columns: map[string]Column{"amount": {Type: "int"}}}
+	desired := &Table{Name: "events", Columns: map[string]Column{"amount": {Type: "bigint"}}}
+
+	diff := soleDiff(t, mustReconcile(t, r, live, desired))
+	if diff.Name != "amount" || diff.Op != AlterColumnType || diff.Column.Type != "bigint" {
+		t.Fatalf("expected AlterColumnType amount->bigint, got %+v", diff)
+	}
+}
+
+func TestReconcileKeepsOutOfBandTypeDriftInsteadOfAltering(t *testing.T) {
+	//lastApplied recorded "int", but live now shows "varchar" - an operator changed it since our last
+	//reconciliation, so the live definition must win even though the column name is present in lastApplied
+	store := &fakeSchemaStateStore{applied: &Table{Name: "events", Columns: map[string]Column{
+		"amount": {Type: "int"},
+	}}}
+	r := NewReconciler("dst", Merge, store)
+
+	live := &Table{Name: "events", Columns: map[string]Column{"amount": {Type: "varchar"}}}
+	desired := &Table{Name: "events", Columns: map[string]Column{"amount": {Type: "bigint"}}}
+
+	diffs := mustReconcile(t, r, live, desired)
+	if len(diffs) != 0 {
+		t.Fatalf("expected no diffs when live has drifted out-of-band, got %+v", diffs)
+	}
+	if store.applied.Columns["amount"].Type != "varchar" {
+		t.Fatalf("expected drifted live type to be persisted as applied, got %+v", store.applied.Columns["amount"])
+	}
+}
+
+func TestReconcileStrictDropsRemovedColumn(t *testing.T) {
+	store := &fakeSchemaStateStore{applied: &Table{Name: "events", Columns: map[string]Column{
+		"legacy_field": {Type: "varchar"},
+	}}}
+	r := NewReconciler("dst", Strict, store)
+
+	live := &Table{Name: "events", Columns: map[string]Column{"legacy_field": {Type: "varchar"}}}
+	desired := &Table{Name: "events", Columns: map[string]Column{}}
+
+	diff := soleDiff(t, mustReconcile(t, r, live, desired))
+	if diff.Name != "legacy_field" || diff.Op != DropColumn {
+		t.Fatalf("expected DropColumn for legacy_field under strict mode, got %+v", diff)
+	}
+}
+
+func TestReconcileMergeLeavesRemovedColumnInPlace(t *testing.T) {
+	store := &fakeSchemaStateStore{applied: &Table{Name: "events", Columns: map[string]Column{
+		"legacy_field": {Type: "varchar"},
+	}}}
+	r := NewReconciler("dst", Merge, store)
+
+	live := &Table{Name: "events", Columns: map[string]Column{"legacy_field": {Type: "varchar"}}}
+	desired := &Table{Name: "events", Columns: map[string]Column{}}
+
+	diffs := mustReconcile(t, r, live, desired)
+	if len(diffs) != 0 {
+		t.Fatalf("expected no diffs for a removed column under merge mode, got %+v", diffs)
+	}
+}
+
+func TestReconcileAdditiveNullsRemovedColumn(t *testing.T) {
+	store := &fakeSchemaStateStore{applied: &Table{Name: "events", Columns: map[string]Column{
+		"legacy_field": {Type: "varchar"},
+	}}}
+	r := NewReconciler("dst", Additive, store)
+
+	live := &Table{Name: "events", Columns: map[string]Column{"legacy_field": {Type: "varchar"}}}
+	desired := &Table{Name: "events", Columns: map[string]Column{}}
+
+	diff := soleDiff(t, mustReconcile(t, r, live, desired))
+	if diff.Name != "legacy_field" || diff.Op != NullColumn {
+		t.Fatalf("expected NullColumn for legacy_field under additive mode, got %+v", diff)
+	}
+}
+
+func mustReconcile(t *testing.T, r *Reconciler, live, desired *Table) []ColumnDiff {
+	t.Helper()
+	diffs, err := r.Reconcile("events", live, desired)
+	if err != nil {
+		t.Fatalf("Reconcile() unexpected error: %v", err)
+	}
+	return diffs
+}
@@ -0,0 +1,126 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"text/template"
+
+	"github.com/jitsucom/eventnative/events"
+)
+
+//RoutingRule is a single per-event routing predicate evaluated ahead of mapping/enrichment.
+//When is a go-template predicate string; the rule matches only when it renders to "true" (or When is empty).
+//JSONPath expressions are not evaluated.
+type RoutingRule struct {
+	When              string `mapstructure:"when" json:"when,omitempty" yaml:"when,omitempty"`
+	TableNameTemplate string `mapstructure:"table_name_template" json:"table_name_template,omitempty" yaml:"table_name_template,omitempty"`
+	Drop              bool   `mapstructure:"drop" json:"drop,omitempty" yaml:"drop,omitempty"`
+}
+
+//RouterResult is the outcome of evaluating the routing rules against a single event
+type RouterResult struct {
+	//Dropped is true if the event matched a drop rule and must not be persisted
+	Dropped bool
+	//TableNameTemplate overrides the destination's default table name template when a rule matched
+	TableNameTemplate string
+	//Matched is true if any rule matched, false if the event fell through to the default table
+	Matched bool
+}
+
+//Router evaluates an ordered list of RoutingRule against each event before MappingStep runs. Route runs
+//concurrently with every other event on the same destination, so matched/unmatched/dropped are only ever
+//touched via sync/atomic.
+type Router struct {
+	destinationName string
+	rules           []*compiledRule
+
+	matched   int64
+	unmatched int64
+	dropped   int64
+}
+
+type compiledRule struct {
+	predicate         Predicate
+	tableNameTemplate string
+	drop              bool
+}
+
+//Predicate evaluates a routing rule's "when" expression against an event
+type Predicate func(event events.Event) (bool, error)
+
+//NewRouter compiles routing rules and returns a Router, or nil if no rules are configured
+func NewRouter(destinationName string, rules []*RoutingRule) (*Router, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	compiled := make([]*compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		predicate, err := compilePredicate(rule.When)
+		if err != nil {
+			return nil, fmt.Errorf("Error compiling routing rule [%s]: %v", rule.When, err)
+		}
+
+		compiled = append(compiled, &compiledRule{
+			predicate:         predicate,
+			tableNameTemplate: rule.TableNameTemplate,
+			drop:              rule.Drop,
+		})
+	}
+
+	return &Router{destinationName: destinationName, rules: compiled}, nil
+}
+
+//Route evaluates rules in order and returns the first match, or an unmatched result if none apply
+func (r *Router) Route(event events.Event) (RouterResult, error) {
+	for _, rule := range r.rules {
+		matches, err := rule.predicate(event)
+		if err != nil {
+			return RouterResult{}, fmt.Errorf("[%s] Error evaluating routing rule: %v", r.destinationName, err)
+		}
+
+		if !matches {
+			continue
+		}
+
+		atomic.AddInt64(&r.matched, 1)
+		if rule.drop {
+			atomic.AddInt64(&r.dropped, 1)
+			return RouterResult{Dropped: true, Matched: true}, nil
+		}
+
+		return RouterResult{TableNameTemplate: rule.tableNameTemplate, Matched: true}, nil
+	}
+
+	atomic.AddInt64(&r.unmatched, 1)
+	return RouterResult{Matched: false}, nil
+}
+
+//Stats returns matched/unmatched/dropped counters for metrics reporting
+func (r *Router) Stats() (matched, unmatched, dropped int64) {
+	return atomic.LoadInt64(&r.matched), atomic.LoadInt64(&r.unmatched), atomic.LoadInt64(&r.dropped)
+}
+
+//compilePredicate turns a "when" expression into a Predicate. The expression is a go-template
+//that is considered a match when it renders to "true" (e.g. "{{eq .event_type \"pageview\"}}").
+//An empty "when" always matches, which is useful for a final catch-all/drop rule.
+func compilePredicate(when string) (Predicate, error) {
+	if when == "" {
+		return func(event events.Event) (bool, error) { return true, nil }, nil
+	}
+
+	tmpl, err := template.New("routing_rule").Parse(when)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(event events.Event) (bool, error) {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, map[string]interface{}(event)); err != nil {
+			return false, err
+		}
+		return strings.TrimSpace(buf.String()) == "true", nil
+	}, nil
+}
@@ -0,0 +1,104 @@
+package schema
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/jitsucom/eventnative/events"
+)
+
+func TestRouterRouteMatchOverridesTable(t *testing.T) {
+	router, err := NewRouter("test_dst", []*RoutingRule{
+		{When: `{{eq .event_type "transaction"}}`, TableNameTemplate: "transactions"},
+	})
+	if err != nil {
+		t.Fatalf("NewRouter() unexpected error: %v", err)
+	}
+
+	result, err := router.Route(events.Event{"event_type": "transaction"})
+	if err != nil {
+		t.Fatalf("Route() unexpected error: %v", err)
+	}
+
+	if !result.Matched || result.Dropped || result.TableNameTemplate != "transactions" {
+		t.Fatalf("expected a match routing to transactions, got %+v", result)
+	}
+
+	matched, unmatched, dropped := router.Stats()
+	if matched != 1 || unmatched != 0 || dropped != 0 {
+		t.Fatalf("expected stats (1,0,0), got (%d,%d,%d)", matched, unmatched, dropped)
+	}
+}
+
+func TestRouterRouteDrop(t *testing.T) {
+	router, err := NewRouter("test_dst", []*RoutingRule{
+		{When: `{{eq .event_type "test"}}`, Drop: true},
+	})
+	if err != nil {
+		t.Fatalf("NewRouter() unexpected error: %v", err)
+	}
+
+	result, err := router.Route(events.Event{"event_type": "test"})
+	if err != nil {
+		t.Fatalf("Route() unexpected error: %v", err)
+	}
+
+	if !result.Dropped {
+		t.Fatalf("expected event to be dropped, got %+v", result)
+	}
+
+	_, _, dropped := router.Stats()
+	if dropped != 1 {
+		t.Fatalf("expected dropped count 1, got %d", dropped)
+	}
+}
+
+func TestRouterRouteUnmatchedFallsThrough(t *testing.T) {
+	router, err := NewRouter("test_dst", []*RoutingRule{
+		{When: `{{eq .event_type "transaction"}}`, TableNameTemplate: "transactions"},
+	})
+	if err != nil {
+		t.Fatalf("NewRouter() unexpected error: %v", err)
+	}
+
+	result, err := router.Route(events.Event{"event_type": "pageview"})
+	if err != nil {
+		t.Fatalf("Route() unexpected error: %v", err)
+	}
+
+	if result.Matched {
+		t.Fatalf("expected no rule to match, got %+v", result)
+	}
+
+	_, unmatched, _ := router.Stats()
+	if unmatched != 1 {
+		t.Fatalf("expected unmatched count 1, got %d", unmatched)
+	}
+}
+
+func TestRouterRouteStatsAreRaceFreeUnderConcurrentEvents(t *testing.T) {
+	router, err := NewRouter("test_dst", []*RoutingRule{
+		{When: `{{eq .event_type "transaction"}}`, TableNameTemplate: "transactions"},
+	})
+	if err != nil {
+		t.Fatalf("NewRouter() unexpected error: %v", err)
+	}
+
+	const goroutines = 50
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+	for i := 0; i < goroutines; i++ {
+		go func() {
+			defer wg.Done()
+			if _, err := router.Route(events.Event{"event_type": "pageview"}); err != nil {
+				t.Errorf("Route() unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	_, unmatched, _ := router.Stats()
+	if unmatched != goroutines {
+		t.Fatalf("expected unmatched count %d after concurrent routing, got %d", goroutines, unmatched)
+	}
+}
@@ -0,0 +1,44 @@
+package schema
+
+import "github.com/jitsucom/eventnative/events"
+
+//Column is a single column's SQL type, as tracked across the live, last-applied and desired schemas
+type Column struct {
+	Type string
+}
+
+//Table is a destination table's schema: its name and the set of columns Jitsu knows about
+type Table struct {
+	Name    string
+	Columns map[string]Column
+}
+
+//TableFromEvent infers the schema contribution of a single mapped event: the SQL type of every field it
+//carries. Callers accumulate this across every event a table has seen (see sqlStorage.mergeDesired)
+//to build the actual desired schema - a single event's fields are never, by themselves, the full desired
+//schema, since most event shapes only ever populate a subset of a table's columns.
+func TableFromEvent(tableName string, event events.Event) *Table {
+	table := &Table{Name: tableName, Columns: map[string]Column{}}
+	for field, value := range event {
+		table.Columns[field] = Column{Type: sqlTypeOf(value)}
+	}
+	return table
+}
+
+//sqlTypeOf maps a decoded JSON value to the SQL column type used to store it. Event values only ever
+//decode to the types encoding/json produces, plus bool and nil.
+func sqlTypeOf(value interface{}) string {
+	switch v := value.(type) {
+	case bool:
+		return "boolean"
+	case float64:
+		if v == float64(int64(v)) {
+			return "bigint"
+		}
+		return "double precision"
+	case int, int64:
+		return "bigint"
+	default:
+		return "varchar"
+	}
+}
@@ -0,0 +1,30 @@
+package schema
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	"github.com/jitsucom/eventnative/events"
+)
+
+//ExecuteNameTemplate renders a table/topic name template (e.g. "events_{{.event_type}}") against a single
+//event. A template with no actions is returned unchanged without invoking the template engine, so a plain
+//static name is just as valid as before templating support existed.
+func ExecuteNameTemplate(tmplString string, event events.Event) (string, error) {
+	if !strings.Contains(tmplString, "{{") {
+		return tmplString, nil
+	}
+
+	tmpl, err := template.New("name_template").Parse(tmplString)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, map[string]interface{}(event)); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
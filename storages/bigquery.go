@@ -0,0 +1,27 @@
+package storages
+
+import (
+	"fmt"
+
+	"github.com/jitsucom/eventnative/adapters"
+)
+
+//BigQueryType is the DestinationConfig.Type value for Google BigQuery
+const BigQueryType = "bigquery"
+
+//NewBigQuery returns configured BigQuery storage. When config.reconciler is configured, an Insert whose
+//event brings a new column or column type first reconciles tableName's schema, before the adapter issues
+//any ALTER TABLE/INSERT.
+func NewBigQuery(config *Config) (Storage, error) {
+	googleConfig := config.destination.Google
+	if googleConfig == nil {
+		return nil, fmt.Errorf("[%s] BigQuery destination requires the 'google' config section", config.name)
+	}
+
+	adapter, err := adapters.NewBigQuery(config.ctx, googleConfig, config.sqlTypeCasts)
+	if err != nil {
+		return nil, fmt.Errorf("[%s] Error creating BigQuery adapter: %v", config.name, err)
+	}
+
+	return newSQLStorage(config, BigQueryType, adapter), nil
+}
@@ -0,0 +1,27 @@
+package storages
+
+import (
+	"fmt"
+
+	"github.com/jitsucom/eventnative/adapters"
+)
+
+//ClickHouseType is the DestinationConfig.Type value for ClickHouse
+const ClickHouseType = "clickhouse"
+
+//NewClickHouse returns configured ClickHouse storage. When config.reconciler is configured, an Insert whose
+//event brings a new column or column type first reconciles tableName's schema, before the adapter issues
+//any ALTER TABLE/INSERT.
+func NewClickHouse(config *Config) (Storage, error) {
+	chConfig := config.destination.ClickHouse
+	if chConfig == nil {
+		return nil, fmt.Errorf("[%s] ClickHouse destination requires the 'clickhouse' config section", config.name)
+	}
+
+	adapter, err := adapters.NewClickHouse(config.ctx, chConfig, config.sqlTypeCasts)
+	if err != nil {
+		return nil, fmt.Errorf("[%s] Error creating ClickHouse adapter: %v", config.name, err)
+	}
+
+	return newSQLStorage(config, ClickHouseType, adapter), nil
+}
@@ -17,6 +17,8 @@ const (
 
 	BatchMode  = "batch"
 	StreamMode = "stream"
+
+	KafkaType = "kafka"
 )
 
 var unknownDestination = errors.New("Unknown destination type")
@@ -29,11 +31,21 @@ type DestinationConfig struct {
 	Enrichment   []*enrichment.RuleConfig `mapstructure:"enrichment" json:"enrichment,omitempty" yaml:"enrichment,omitempty"`
 	BreakOnError bool                     `mapstructure:"break_on_error" json:"break_on_error,omitempty" yaml:"break_on_error,omitempty"`
 
+	//Incomplete is an optional sidechannel destination that receives every event for which the
+	//enrichment/mapping pipeline accumulated one or more errors, instead of short-circuiting the batch.
+	//Such events are written to this destination only, not to the primary one.
+	Incomplete *DestinationConfig `mapstructure:"incomplete" json:"incomplete,omitempty" yaml:"incomplete,omitempty"`
+
+	//Routing is an ordered list of per-event rules evaluated ahead of mapping/enrichment, letting a single
+	//destination fan events out to different tables or silently drop them.
+	Routing []*schema.RoutingRule `mapstructure:"routing" json:"routing,omitempty" yaml:"routing,omitempty"`
+
 	DataSource *adapters.DataSourceConfig `mapstructure:"datasource" json:"datasource,omitempty" yaml:"datasource,omitempty"`
 	S3         *adapters.S3Config         `mapstructure:"s3" json:"s3,omitempty" yaml:"s3,omitempty"`
 	Google     *adapters.GoogleConfig     `mapstructure:"google" json:"google,omitempty" yaml:"google,omitempty"`
 	ClickHouse *adapters.ClickHouseConfig `mapstructure:"clickhouse" json:"clickhouse,omitempty" yaml:"clickhouse,omitempty"`
 	Snowflake  *adapters.SnowflakeConfig  `mapstructure:"snowflake" json:"snowflake,omitempty" yaml:"snowflake,omitempty"`
+	Kafka      *adapters.KafkaConfig      `mapstructure:"kafka" json:"kafka,omitempty" yaml:"kafka,omitempty"`
 }
 
 type DataLayout struct {
@@ -42,13 +54,18 @@ type DataLayout struct {
 	Mappings          *schema.Mapping         `mapstructure:"mappings" json:"mappings,omitempty" yaml:"mappings,omitempty"`
 	TableNameTemplate string                  `mapstructure:"table_name_template" json:"table_name_template,omitempty" yaml:"table_name_template,omitempty"`
 	PrimaryKeyFields  []string                `mapstructure:"primary_key_fields" json:"primary_key_fields,omitempty" yaml:"primary_key_fields,omitempty"`
+	//ReconcileMode controls how columns removed from the desired schema are treated when Jitsu reconciles
+	//its last-applied schema against the live DB schema: strict|merge|additive. Defaults to merge.
+	ReconcileMode schema.ReconcileMode `mapstructure:"reconcile_mode" json:"reconcile_mode,omitempty" yaml:"reconcile_mode,omitempty"`
 }
 
 type Config struct {
 	ctx           context.Context
 	name          string
 	destination   *DestinationConfig
+	router        *schema.Router
 	processor     *schema.MappingStep
+	reconciler    *schema.Reconciler
 	streamMode    bool
 	monitorKeeper MonitorKeeper
 	eventQueue    *events.PersistentQueue
@@ -56,6 +73,8 @@ type Config struct {
 	loggerFactory *logging.Factory
 	pkFields      map[string]bool
 	sqlTypeCasts  map[string]string
+
+	incompleteStorage events.StorageProxy
 }
 
 //Create event storage proxy and event consumer (logger or event-queue)
@@ -76,6 +95,7 @@ func Create(ctx context.Context, name, logEventPath string, destination Destinat
 	var newStyleMapping *schema.Mapping
 	pkFields := map[string]bool{}
 	mappingFieldType := schema.Default
+	reconcileMode := schema.Merge
 	if destination.DataLayout != nil {
 		mappingFieldType = destination.DataLayout.MappingType
 		oldStyleMappings = destination.DataLayout.Mapping
@@ -85,11 +105,22 @@ func Create(ctx context.Context, name, logEventPath string, destination Destinat
 			tableName = destination.DataLayout.TableNameTemplate
 		}
 
+		if destination.DataLayout.ReconcileMode != "" {
+			reconcileMode = destination.DataLayout.ReconcileMode
+		}
+
 		for _, field := range destination.DataLayout.PrimaryKeyFields {
 			pkFields[field] = true
 		}
 	}
 
+	//Kafka's own "topic" config is its table name equivalent; fall back to it before the generic default so
+	//a Kafka destination configured only via kafka.topic still gets Router/MappingStep's resolved tableName
+	//threaded into Insert instead of Kafka silently re-deriving its own topic and ignoring routing overrides
+	if tableName == "" && destination.Type == KafkaType && destination.Kafka != nil && destination.Kafka.Topic != "" {
+		tableName = destination.Kafka.Topic
+	}
+
 	if tableName == "" {
 		tableName = defaultTableName
 		logging.Infof("[%s] uses default table name: %s", name, tableName)
@@ -99,6 +130,12 @@ func Create(ctx context.Context, name, logEventPath string, destination Destinat
 		return nil, nil, fmt.Errorf("Unknown destination mode: %s. Available mode: [%s, %s]", destination.Mode, BatchMode, StreamMode)
 	}
 
+	if destination.Type == S3Type && destination.S3 != nil {
+		if err := destination.S3.Validate(); err != nil {
+			return nil, nil, fmt.Errorf("Error validating S3 config for [%s]: %v", name, err)
+		}
+	}
+
 	if len(destination.Enrichment) == 0 {
 		logging.Warnf("[%s] doesn't have enrichment rules", name)
 	} else {
@@ -147,10 +184,31 @@ func Create(ctx context.Context, name, logEventPath string, destination Destinat
 		logging.Warnf("[%s] doesn't have mapping rules", name)
 	}
 
-	processor, err := schema.NewMappingStep(name, tableName, fieldMapper, enrichmentRules, destination.BreakOnError)
+	//an incomplete events destination needs the full list of errors for every event, not just the first one,
+	//so break-on-error semantics are disabled whenever one is configured
+	breakOnError := destination.BreakOnError && destination.Incomplete == nil
+
+	processor, err := schema.NewMappingStep(name, tableName, fieldMapper, enrichmentRules, breakOnError)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	router, err := schema.NewRouter(name, destination.Routing)
 	if err != nil {
 		return nil, nil, err
 	}
+	if router != nil {
+		logging.Infof("[%s] Configured %d routing rule(s)", name, len(destination.Routing))
+	}
+
+	var reconciler *schema.Reconciler
+	if isSQLDestination(destination.Type) {
+		if schemaStateStore, ok := monitorKeeper.(schema.SchemaStateStore); ok {
+			reconciler = schema.NewReconciler(name, reconcileMode, schemaStateStore)
+		} else {
+			logging.Warnf("[%s] monitor keeper doesn't support schema state persistence, falling back to per-batch schema inference", name)
+		}
+	}
 
 	var eventQueue *events.PersistentQueue
 	if destination.Mode == StreamMode {
@@ -160,18 +218,43 @@ func Create(ctx context.Context, name, logEventPath string, destination Destinat
 		}
 	}
 
+	var incompleteStorage events.StorageProxy
+	if destination.Incomplete != nil {
+		//an incomplete destination pointing at itself (directly or through a longer chain) would recurse into
+		//Create forever, so incomplete destinations are forbidden from declaring their own Incomplete
+		if destination.Incomplete.Incomplete != nil {
+			if eventQueue != nil {
+				eventQueue.Close()
+			}
+			return nil, nil, fmt.Errorf("[%s] Error creating incomplete events destination: incomplete destinations can't declare their own 'incomplete'", name)
+		}
+
+		logging.Infof("[%s] Configured incomplete events destination: %s", name, destination.Incomplete.Type)
+
+		incompleteStorage, _, err = Create(ctx, name+"_incomplete", logEventPath, *destination.Incomplete, monitorKeeper, eventsCache, loggerFactory)
+		if err != nil {
+			if eventQueue != nil {
+				eventQueue.Close()
+			}
+			return nil, nil, fmt.Errorf("Error creating incomplete events destination for [%s]: %v", name, err)
+		}
+	}
+
 	storageConfig := &Config{
-		ctx:           ctx,
-		name:          name,
-		destination:   &destination,
-		processor:     processor,
-		streamMode:    destination.Mode == StreamMode,
-		monitorKeeper: monitorKeeper,
-		eventQueue:    eventQueue,
-		eventsCache:   eventsCache,
-		loggerFactory: loggerFactory,
-		pkFields:      pkFields,
-		sqlTypeCasts:  sqlTypeCasts,
+		ctx:               ctx,
+		name:              name,
+		destination:       &destination,
+		router:            router,
+		processor:         processor,
+		reconciler:        reconciler,
+		streamMode:        destination.Mode == StreamMode,
+		monitorKeeper:     monitorKeeper,
+		eventQueue:        eventQueue,
+		eventsCache:       eventsCache,
+		loggerFactory:     loggerFactory,
+		pkFields:          pkFields,
+		sqlTypeCasts:      sqlTypeCasts,
+		incompleteStorage: incompleteStorage,
 	}
 
 	var storageProxy events.StorageProxy
@@ -188,12 +271,28 @@ func Create(ctx context.Context, name, logEventPath string, destination Destinat
 		storageProxy = newProxy(NewS3, storageConfig)
 	case SnowflakeType:
 		storageProxy = newProxy(NewSnowflake, storageConfig)
+	case KafkaType:
+		storageProxy = newProxy(NewKafka, storageConfig)
 	default:
 		if eventQueue != nil {
 			eventQueue.Close()
 		}
+		if incompleteStorage != nil {
+			incompleteStorage.Close()
+		}
 		return nil, nil, unknownDestination
 	}
 
 	return storageProxy, eventQueue, nil
 }
+
+//isSQLDestination returns true for every destination type backed by a SQL table, i.e. everywhere
+//schema reconciliation via ALTER TABLE applies
+func isSQLDestination(destinationType string) bool {
+	switch destinationType {
+	case RedshiftType, BigQueryType, PostgresType, ClickHouseType, SnowflakeType:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,139 @@
+package storages
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Shopify/sarama"
+	"github.com/jitsucom/eventnative/adapters"
+	"github.com/jitsucom/eventnative/events"
+	"github.com/jitsucom/eventnative/logging"
+	"github.com/jitsucom/eventnative/schema"
+)
+
+//Kafka is a Storage that streams every event onto a Kafka topic as soon as it is enriched/mapped,
+//bypassing the warehouse batching/upload path entirely
+type Kafka struct {
+	name     string
+	config   *adapters.KafkaConfig
+	producer sarama.SyncProducer
+	//pkFields are the same primary key fields configured via DataLayout.PrimaryKeyFields for every other
+	//destination type; Kafka reuses them as the partition key instead of introducing a separate field.
+	pkFields map[string]bool
+}
+
+//NewKafka returns configured Kafka storage
+func NewKafka(config *Config) (Storage, error) {
+	kafkaConfig := config.destination.Kafka
+	if err := kafkaConfig.Validate(); err != nil {
+		return nil, err
+	}
+
+	saramaConfig := sarama.NewConfig()
+	saramaConfig.Producer.Return.Successes = true
+	saramaConfig.Producer.RequiredAcks = sarama.WaitForAll
+
+	switch kafkaConfig.Compression {
+	case "gzip":
+		saramaConfig.Producer.Compression = sarama.CompressionGZIP
+	case "snappy":
+		saramaConfig.Producer.Compression = sarama.CompressionSnappy
+	case "lz4":
+		saramaConfig.Producer.Compression = sarama.CompressionLZ4
+	}
+
+	if sasl := kafkaConfig.SASL; sasl != nil {
+		saramaConfig.Net.SASL.Enable = true
+		saramaConfig.Net.SASL.Mechanism = sarama.SASLMechanism(sasl.Mechanism)
+		saramaConfig.Net.SASL.User = sasl.Username
+		saramaConfig.Net.SASL.Password = sasl.Password
+		saramaConfig.Net.TLS.Enable = sasl.TLS
+	}
+
+	producer, err := sarama.NewSyncProducer(kafkaConfig.Brokers, saramaConfig)
+	if err != nil {
+		return nil, fmt.Errorf("[%s] Error creating Kafka producer: %v", config.name, err)
+	}
+
+	logging.Infof("[%s] Initialized Kafka producer for brokers: %v topic: %s", config.name, kafkaConfig.Brokers, kafkaConfig.Topic)
+
+	return &Kafka{
+		name:     config.name,
+		config:   kafkaConfig,
+		producer: producer,
+		pkFields: config.pkFields,
+	}, nil
+}
+
+//Insert renders tableName - already resolved by MappingStep and any matching Router rule, per the Storage
+//contract in proxy.go - as the topic template (the same templating semantics as DataLayout.TableNameTemplate)
+//and sends the event to Kafka, keyed by the destination's configured PrimaryKeyFields when present. This way
+//a routing rule's table_name_template actually redirects Kafka events to a different topic instead of being
+//silently ignored in favor of the destination's static kafka.topic.
+func (k *Kafka) Insert(tableName string, event events.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("[%s] Error serializing event for Kafka: %v", k.name, err)
+	}
+
+	topic, err := schema.ExecuteNameTemplate(tableName, event)
+	if err != nil {
+		return fmt.Errorf("[%s] Error rendering Kafka topic template [%s]: %v", k.name, tableName, err)
+	}
+
+	msg := &sarama.ProducerMessage{
+		Topic: topic,
+		Value: sarama.ByteEncoder(payload),
+	}
+
+	if key := k.partitionKey(event); key != "" {
+		msg.Key = sarama.StringEncoder(key)
+	}
+
+	_, _, err = k.producer.SendMessage(msg)
+	if err != nil {
+		return fmt.Errorf("[%s] Error sending event to Kafka topic [%s]: %v", k.name, topic, err)
+	}
+
+	return nil
+}
+
+//partitionKey concatenates the configured primary key field values (sorted by field name for a stable
+//key) so events sharing a primary key land on the same partition
+func (k *Kafka) partitionKey(event events.Event) string {
+	if len(k.pkFields) == 0 {
+		return ""
+	}
+
+	fields := make([]string, 0, len(k.pkFields))
+	for field := range k.pkFields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	values := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if value, ok := event[field]; ok {
+			values = append(values, fmt.Sprintf("%v", value))
+		}
+	}
+
+	return strings.Join(values, "_")
+}
+
+//Name returns destination name
+func (k *Kafka) Name() string {
+	return k.name
+}
+
+//Type returns Kafka type
+func (k *Kafka) Type() string {
+	return KafkaType
+}
+
+//Close closes the underlying Kafka producer
+func (k *Kafka) Close() error {
+	return k.producer.Close()
+}
@@ -0,0 +1,25 @@
+package storages
+
+import (
+	"testing"
+
+	"github.com/jitsucom/eventnative/events"
+)
+
+func TestKafkaPartitionKey(t *testing.T) {
+	k := &Kafka{pkFields: map[string]bool{"user_id": true, "event_id": true}}
+
+	key := k.partitionKey(events.Event{"user_id": "u1", "event_id": "e1", "event_type": "pageview"})
+	if key != "e1_u1" {
+		t.Fatalf("expected sorted-by-field-name key \"e1_u1\", got %q", key)
+	}
+}
+
+func TestKafkaPartitionKeyEmptyWithoutPkFields(t *testing.T) {
+	k := &Kafka{}
+
+	key := k.partitionKey(events.Event{"user_id": "u1"})
+	if key != "" {
+		t.Fatalf("expected empty partition key when no pkFields configured, got %q", key)
+	}
+}
@@ -0,0 +1,27 @@
+package storages
+
+import (
+	"fmt"
+
+	"github.com/jitsucom/eventnative/adapters"
+)
+
+//PostgresType is the DestinationConfig.Type value for Postgres
+const PostgresType = "postgres"
+
+//NewPostgres returns configured Postgres storage. When config.reconciler is configured, an Insert whose
+//event brings a new column or column type first reconciles tableName's schema, before the adapter issues
+//any ALTER TABLE/INSERT.
+func NewPostgres(config *Config) (Storage, error) {
+	dsConfig := config.destination.DataSource
+	if dsConfig == nil {
+		return nil, fmt.Errorf("[%s] Postgres destination requires the 'datasource' config section", config.name)
+	}
+
+	adapter, err := adapters.NewPostgres(config.ctx, dsConfig, config.sqlTypeCasts)
+	if err != nil {
+		return nil, fmt.Errorf("[%s] Error creating Postgres adapter: %v", config.name, err)
+	}
+
+	return newSQLStorage(config, PostgresType, adapter), nil
+}
@@ -0,0 +1,96 @@
+package storages
+
+import (
+	"fmt"
+
+	"github.com/jitsucom/eventnative/events"
+	"github.com/jitsucom/eventnative/logging"
+)
+
+//Storage is implemented by every concrete destination (Redshift, Postgres, S3, Kafka, ...) and is wrapped
+//by newProxy into the common events.StorageProxy surface. tableName is resolved by MappingStep (and
+//overridden by a matching Router rule) before Insert is called, so concrete destinations never need to
+//re-derive it themselves.
+type Storage interface {
+	Insert(tableName string, event events.Event) error
+	Name() string
+	Type() string
+	Close() error
+}
+
+//proxy adapts a concrete Storage to events.StorageProxy, running mapping/enrichment ahead of every Insert
+//and diverting events that accumulated enrichment errors to the configured incomplete-events destination
+type proxy struct {
+	config  *Config
+	storage Storage
+}
+
+//newProxy builds storage via constructor and wraps it with the mapping/routing/incomplete-events pipeline
+//shared by every destination type
+func newProxy(constructor func(config *Config) (Storage, error), config *Config) events.StorageProxy {
+	storage, err := constructor(config)
+	if err != nil {
+		logging.Errorf("[%s] Error initializing storage: %v", config.name, err)
+	}
+
+	return &proxy{config: config, storage: storage}
+}
+
+//Insert routes, maps/enriches and finally persists a single event. A matching Router rule can drop the
+//event outright or override the table/topic it lands in ahead of MappingStep. Events for which the
+//mapping step accumulated one or more enrichment errors are sent to the configured incomplete-events
+//destination instead of the primary storage; when no incomplete destination is configured they are
+//persisted to the primary storage as usual, unless break_on_error is set, in which case Process returns an
+//error and the event is not persisted.
+func (p *proxy) Insert(event events.Event) error {
+	var tableNameOverride string
+	if p.config.router != nil {
+		result, err := p.config.router.Route(event)
+		if err != nil {
+			return err
+		}
+		if result.Dropped {
+			return nil
+		}
+		tableNameOverride = result.TableNameTemplate
+	}
+
+	mapped, tableName, derivedErrors, err := p.config.processor.Process(event, tableNameOverride)
+	if err != nil {
+		return err
+	}
+
+	if len(derivedErrors) > 0 && p.config.incompleteStorage != nil {
+		return p.config.incompleteStorage.Insert(mapped)
+	}
+
+	if p.storage == nil {
+		return fmt.Errorf("[%s] storage is not initialized", p.config.name)
+	}
+
+	return p.storage.Insert(tableName, mapped)
+}
+
+//RoutingStats exposes matched/unmatched/dropped routing counters for this destination so they can be
+//surfaced as metrics; it returns all zeros when no routing rules are configured. Part of
+//events.StorageProxy, so it's reachable by every caller holding the proxy, not just within package storages.
+func (p *proxy) RoutingStats() (matched, unmatched, dropped int64) {
+	if p.config.router == nil {
+		return 0, 0, 0
+	}
+	return p.config.router.Stats()
+}
+
+//Close closes the underlying storage and, if configured, the incomplete-events destination
+func (p *proxy) Close() error {
+	var err error
+	if p.storage != nil {
+		err = p.storage.Close()
+	}
+	if p.config.incompleteStorage != nil {
+		if closeErr := p.config.incompleteStorage.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
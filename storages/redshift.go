@@ -0,0 +1,27 @@
+package storages
+
+import (
+	"fmt"
+
+	"github.com/jitsucom/eventnative/adapters"
+)
+
+//RedshiftType is the DestinationConfig.Type value for AWS Redshift
+const RedshiftType = "redshift"
+
+//NewAwsRedshift returns configured AWS Redshift storage. When config.reconciler is configured, an Insert
+//whose event brings a new column or column type first reconciles tableName's schema, before the adapter
+//issues any ALTER TABLE/INSERT.
+func NewAwsRedshift(config *Config) (Storage, error) {
+	dsConfig := config.destination.DataSource
+	if dsConfig == nil {
+		return nil, fmt.Errorf("[%s] Redshift destination requires the 'datasource' config section", config.name)
+	}
+
+	adapter, err := adapters.NewAwsRedshift(config.ctx, dsConfig, config.destination.S3, config.sqlTypeCasts)
+	if err != nil {
+		return nil, fmt.Errorf("[%s] Error creating Redshift adapter: %v", config.name, err)
+	}
+
+	return newSQLStorage(config, RedshiftType, adapter), nil
+}
@@ -0,0 +1,167 @@
+package storages
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/jitsucom/eventnative/adapters"
+	"github.com/jitsucom/eventnative/events"
+	"github.com/jitsucom/eventnative/logging"
+)
+
+//S3 is a Storage that uploads every event as an object into the configured bucket, in the same TSV/JSONL
+//format used by the other batch destinations
+type S3 struct {
+	name   string
+	config *adapters.S3Config
+	client *s3.S3
+}
+
+//NewS3 returns configured S3 storage. Encryption settings (if any) are applied to every PutObject call and,
+//if a Lifecycle rule is configured, it is applied to the bucket once at startup so operators don't need to
+//configure retention out-of-band.
+func NewS3(config *Config) (Storage, error) {
+	s3Config := config.destination.S3
+	if err := s3Config.Validate(); err != nil {
+		return nil, err
+	}
+
+	awsConfig := aws.NewConfig().WithRegion(s3Config.Region)
+	if s3Config.AccessKeyID != "" {
+		awsConfig = awsConfig.WithCredentials(credentials.NewStaticCredentials(s3Config.AccessKeyID, s3Config.SecretKey, ""))
+	}
+	if s3Config.Endpoint != "" {
+		awsConfig = awsConfig.WithEndpoint(s3Config.Endpoint).WithS3ForcePathStyle(true)
+	}
+
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("[%s] Error creating AWS session for S3: %v", config.name, err)
+	}
+
+	s3Storage := &S3{name: config.name, config: s3Config, client: s3.New(sess)}
+
+	if s3Config.Lifecycle != nil {
+		if err := s3Storage.applyLifecycle(); err != nil {
+			return nil, err
+		}
+	}
+
+	return s3Storage, nil
+}
+
+//applyLifecycle pushes the configured retention rule to the bucket once at startup
+func (s *S3) applyLifecycle() error {
+	lc := s.config.Lifecycle
+
+	rule := &s3.LifecycleRule{
+		ID:     aws.String(fmt.Sprintf("%s-jitsu-retention", s.name)),
+		Status: aws.String("Enabled"),
+		Filter: &s3.LifecycleRuleFilter{Prefix: aws.String(lc.Prefix)},
+	}
+
+	if lc.DaysToGlacier > 0 {
+		rule.Transitions = []*s3.Transition{{Days: aws.Int64(lc.DaysToGlacier), StorageClass: aws.String(s3.TransitionStorageClassGlacier)}}
+	}
+	if lc.DaysToExpire > 0 {
+		rule.Expiration = &s3.LifecycleExpiration{Days: aws.Int64(lc.DaysToExpire)}
+	}
+	if lc.AbortIncompleteMultipartDays > 0 {
+		rule.AbortIncompleteMultipartUpload = &s3.AbortIncompleteMultipartUpload{DaysAfterInitiation: aws.Int64(lc.AbortIncompleteMultipartDays)}
+	}
+
+	_, err := s.client.PutBucketLifecycleConfiguration(&s3.PutBucketLifecycleConfigurationInput{
+		Bucket:                 aws.String(s.config.Bucket),
+		LifecycleConfiguration: &s3.BucketLifecycleConfiguration{Rules: []*s3.LifecycleRule{rule}},
+	})
+	if err != nil {
+		return fmt.Errorf("[%s] Error applying S3 bucket lifecycle configuration: %v", s.name, err)
+	}
+
+	logging.Infof("[%s] Applied S3 bucket lifecycle rule on prefix [%s]", s.name, lc.Prefix)
+	return nil
+}
+
+//Insert uploads a single event under the destination's folder/tableName, applying server-side encryption
+//on every call when configured
+func (s *S3) Insert(tableName string, event events.Event) error {
+	payload, err := formatEvent(event, s.config.Format)
+	if err != nil {
+		return fmt.Errorf("[%s] Error formatting event for S3: %v", s.name, err)
+	}
+
+	key := fmt.Sprintf("%s/%s/%d.log", strings.Trim(s.config.Folder, "/"), tableName, time.Now().UnixNano())
+
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(s.config.Bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(payload),
+	}
+
+	if enc := s.config.Encryption; enc != nil {
+		input.ServerSideEncryption = aws.String(enc.Algorithm)
+		if enc.Algorithm == "aws:kms" && enc.KMSKeyID != "" {
+			input.SSEKMSKeyId = aws.String(enc.KMSKeyID)
+		}
+		if enc.BucketKeyEnabled {
+			input.BucketKeyEnabled = aws.Bool(true)
+		}
+	}
+
+	if _, err := s.client.PutObject(input); err != nil {
+		return fmt.Errorf("[%s] Error uploading event to S3 key [%s]: %v", s.name, key, err)
+	}
+
+	return nil
+}
+
+//Name returns destination name
+func (s *S3) Name() string {
+	return s.name
+}
+
+//Type returns S3 type
+func (s *S3) Type() string {
+	return S3Type
+}
+
+//Close is a no-op: the AWS SDK client requires no explicit teardown
+func (s *S3) Close() error {
+	return nil
+}
+
+//formatEvent renders event as a single line in either jsonl (the default) or tsv format
+func formatEvent(event events.Event, format string) ([]byte, error) {
+	if format == "tsv" {
+		return formatEventTSV(event), nil
+	}
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	return append(line, '\n'), nil
+}
+
+func formatEventTSV(event events.Event) []byte {
+	fields := make([]string, 0, len(event))
+	for field := range event {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	values := make([]string, len(fields))
+	for i, field := range fields {
+		values[i] = fmt.Sprintf("%v", event[field])
+	}
+
+	return []byte(strings.Join(values, "\t") + "\n")
+}
@@ -0,0 +1,32 @@
+package storages
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jitsucom/eventnative/events"
+)
+
+func TestFormatEventJSONLDefault(t *testing.T) {
+	line, err := formatEvent(events.Event{"event_type": "pageview"}, "")
+	if err != nil {
+		t.Fatalf("formatEvent() unexpected error: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(line, &decoded); err != nil {
+		t.Fatalf("expected valid JSON line, got %q: %v", line, err)
+	}
+}
+
+func TestFormatEventTSV(t *testing.T) {
+	line, err := formatEvent(events.Event{"a": "1", "b": "2"}, "tsv")
+	if err != nil {
+		t.Fatalf("formatEvent() unexpected error: %v", err)
+	}
+
+	expected := "1\t2\n"
+	if string(line) != expected {
+		t.Fatalf("expected %q, got %q", expected, string(line))
+	}
+}
@@ -0,0 +1,27 @@
+package storages
+
+import (
+	"fmt"
+
+	"github.com/jitsucom/eventnative/adapters"
+)
+
+//SnowflakeType is the DestinationConfig.Type value for Snowflake
+const SnowflakeType = "snowflake"
+
+//NewSnowflake returns configured Snowflake storage. When config.reconciler is configured, an Insert whose
+//event brings a new column or column type first reconciles tableName's schema, before the adapter issues
+//any ALTER TABLE/INSERT.
+func NewSnowflake(config *Config) (Storage, error) {
+	sfConfig := config.destination.Snowflake
+	if sfConfig == nil {
+		return nil, fmt.Errorf("[%s] Snowflake destination requires the 'snowflake' config section", config.name)
+	}
+
+	adapter, err := adapters.NewSnowflake(config.ctx, sfConfig, config.sqlTypeCasts)
+	if err != nil {
+		return nil, fmt.Errorf("[%s] Error creating Snowflake adapter: %v", config.name, err)
+	}
+
+	return newSQLStorage(config, SnowflakeType, adapter), nil
+}
@@ -0,0 +1,128 @@
+package storages
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/jitsucom/eventnative/events"
+	"github.com/jitsucom/eventnative/schema"
+)
+
+//SQLAdapter is the surface every warehouse adapter (Redshift/BigQuery/Postgres/ClickHouse/Snowflake)
+//exposes so sqlStorage can reconcile schema and persist rows without knowing the dialect underneath
+type SQLAdapter interface {
+	GetTableSchema(tableName string) (*schema.Table, error)
+	PatchTableSchema(diffs []schema.ColumnDiff) error
+	Insert(tableName string, event events.Event) error
+	Close() error
+}
+
+//sqlStorage is the Storage implementation shared by every SQL warehouse destination. Schema reconciliation
+//and row persistence live here once instead of being copy-pasted per dialect; NewAwsRedshift/NewPostgres/
+//NewClickHouse/NewSnowflake/NewBigQuery only differ in which SQLAdapter they hand it.
+type sqlStorage struct {
+	name       string
+	destType   string
+	adapter    SQLAdapter
+	reconciler *schema.Reconciler
+
+	desiredMu sync.Mutex
+	//desired accumulates, per table, every column Jitsu knows the table should have: seeded from the live
+	//schema the first time a table is touched (so a process restart never starts from a blank slate), then
+	//grown by every column an event introduces afterwards. It only ever grows: a single event missing a
+	//field other events carry (e.g. "amount" only present on purchase events, not pageviews) must never be
+	//mistaken for that field having been removed from the desired schema.
+	desired map[string]*schema.Table
+}
+
+func newSQLStorage(config *Config, destType string, adapter SQLAdapter) Storage {
+	return &sqlStorage{
+		name:       config.name,
+		destType:   destType,
+		adapter:    adapter,
+		reconciler: config.reconciler,
+		desired:    map[string]*schema.Table{},
+	}
+}
+
+//Insert reconciles tableName's live schema against the accumulated desired schema before writing the row,
+//so every SQL destination converges on the same 3-way-merge behaviour regardless of dialect. Reconciliation
+//is skipped when no Reconciler is configured, e.g. because the monitor keeper doesn't support schema state
+//persistence, and when event introduces no column (or column type) the accumulated desired schema doesn't
+//already have.
+func (s *sqlStorage) Insert(tableName string, event events.Event) error {
+	if s.reconciler != nil {
+		desiredSchema, grew, err := s.mergeDesired(tableName, event)
+		if err != nil {
+			return fmt.Errorf("[%s] Error seeding desired schema for [%s]: %v", s.name, tableName, err)
+		}
+
+		if grew {
+			liveSchema, err := s.adapter.GetTableSchema(tableName)
+			if err != nil {
+				return fmt.Errorf("[%s] Error reading live schema for [%s]: %v", s.name, tableName, err)
+			}
+
+			diffs, err := s.reconciler.Reconcile(tableName, liveSchema, desiredSchema)
+			if err != nil {
+				return fmt.Errorf("[%s] Error reconciling schema for [%s]: %v", s.name, tableName, err)
+			}
+
+			if len(diffs) > 0 {
+				if err := s.adapter.PatchTableSchema(diffs); err != nil {
+					return fmt.Errorf("[%s] Error patching schema for [%s]: %v", s.name, tableName, err)
+				}
+			}
+		}
+	}
+
+	return s.adapter.Insert(tableName, event)
+}
+
+//mergeDesired folds event's fields into the accumulated desired schema for tableName - seeding it from the
+//live schema on the table's first use this process - and reports whether it gained any column that wasn't
+//already there or changed an existing column's type, i.e. whether it's worth paying for a fresh
+//reconciliation.
+func (s *sqlStorage) mergeDesired(tableName string, event events.Event) (*schema.Table, bool, error) {
+	s.desiredMu.Lock()
+	defer s.desiredMu.Unlock()
+
+	table := s.desired[tableName]
+	if table == nil {
+		live, err := s.adapter.GetTableSchema(tableName)
+		if err != nil {
+			return nil, false, err
+		}
+
+		table = &schema.Table{Name: tableName, Columns: map[string]schema.Column{}}
+		for name, column := range live.Columns {
+			table.Columns[name] = column
+		}
+		s.desired[tableName] = table
+	}
+
+	grew := false
+	for field, column := range schema.TableFromEvent(tableName, event).Columns {
+		if existing, exists := table.Columns[field]; !exists || existing.Type != column.Type {
+			table.Columns[field] = column
+			grew = true
+		}
+	}
+
+	return table, grew, nil
+}
+
+//Name returns destination name
+func (s *sqlStorage) Name() string {
+	return s.name
+}
+
+//Type returns the warehouse dialect this storage was created for
+func (s *sqlStorage) Type() string {
+	return s.destType
+}
+
+//Close closes the underlying adapter
+func (s *sqlStorage) Close() error {
+	return s.adapter.Close()
+}
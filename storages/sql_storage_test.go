@@ -0,0 +1,100 @@
+package storages
+
+import (
+	"testing"
+
+	"github.com/jitsucom/eventnative/events"
+	"github.com/jitsucom/eventnative/schema"
+)
+
+type fakeSQLAdapter struct {
+	live         *schema.Table
+	patchedDiffs []schema.ColumnDiff
+}
+
+func (f *fakeSQLAdapter) GetTableSchema(tableName string) (*schema.Table, error) {
+	return f.live, nil
+}
+
+func (f *fakeSQLAdapter) PatchTableSchema(diffs []schema.ColumnDiff) error {
+	f.patchedDiffs = append(f.patchedDiffs, diffs...)
+	return nil
+}
+
+func (f *fakeSQLAdapter) Insert(tableName string, event events.Event) error {
+	return nil
+}
+
+func (f *fakeSQLAdapter) Close() error {
+	return nil
+}
+
+type fakeSchemaStateStore struct {
+	applied *schema.Table
+}
+
+func (f *fakeSchemaStateStore) GetAppliedSchema(destinationName, tableName string) (*schema.Table, error) {
+	return f.applied, nil
+}
+
+func (f *fakeSchemaStateStore) SaveAppliedSchema(destinationName, tableName string, table *schema.Table) error {
+	f.applied = table
+	return nil
+}
+
+func TestSQLStorageInsertDoesNotTreatASparseEventAsColumnRemoval(t *testing.T) {
+	live := &schema.Table{Name: "events", Columns: map[string]schema.Column{
+		"event_type": {Type: "varchar"},
+		"amount":     {Type: "bigint"},
+	}}
+	store := &fakeSchemaStateStore{applied: &schema.Table{Name: "events", Columns: map[string]schema.Column{
+		"event_type": {Type: "varchar"},
+		"amount":     {Type: "bigint"},
+	}}}
+	adapter := &fakeSQLAdapter{live: live}
+
+	config := &Config{name: "dst", reconciler: schema.NewReconciler("dst", schema.Strict, store)}
+	storage := newSQLStorage(config, "postgres", adapter)
+
+	//a pageview event never carries "amount" - that alone must never read as the operator having removed
+	//the column from the desired schema, even under strict mode
+	if err := storage.Insert("events", events.Event{"event_type": "pageview"}); err != nil {
+		t.Fatalf("Insert() unexpected error: %v", err)
+	}
+
+	for _, diff := range adapter.patchedDiffs {
+		if diff.Name == "amount" {
+			t.Fatalf("expected no diff against [amount] just because one event omitted it, got %+v", diff)
+		}
+	}
+}
+
+func TestSQLStorageInsertAddsColumnFromLaterEvent(t *testing.T) {
+	live := &schema.Table{Name: "events", Columns: map[string]schema.Column{
+		"event_type": {Type: "varchar"},
+	}}
+	store := &fakeSchemaStateStore{applied: &schema.Table{Name: "events", Columns: map[string]schema.Column{
+		"event_type": {Type: "varchar"},
+	}}}
+	adapter := &fakeSQLAdapter{live: live}
+
+	config := &Config{name: "dst", reconciler: schema.NewReconciler("dst", schema.Merge, store)}
+	storage := newSQLStorage(config, "postgres", adapter)
+
+	if err := storage.Insert("events", events.Event{"event_type": "pageview"}); err != nil {
+		t.Fatalf("Insert() unexpected error: %v", err)
+	}
+	if err := storage.Insert("events", events.Event{"event_type": "purchase", "amount": float64(42)}); err != nil {
+		t.Fatalf("Insert() unexpected error: %v", err)
+	}
+
+	found := false
+	for _, diff := range adapter.patchedDiffs {
+		if diff.Name == "amount" && diff.Op == schema.AddColumn {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected an AddColumn diff for [amount] once a later event introduces it, got %+v", adapter.patchedDiffs)
+	}
+}